@@ -0,0 +1,348 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/transcribe"
+	"github.com/aws/aws-sdk-go-v2/service/transcribe/types"
+)
+
+const (
+	// defaultChunkThreshold is the input duration above which we split into
+	// chunks instead of submitting a single Transcribe job, since batch jobs
+	// have media length limits and per-job cost/token ceilings.
+	defaultChunkThreshold = 2 * time.Hour
+	defaultChunkDuration  = 30 * time.Minute
+	defaultChunkOverlap   = 30 * time.Second
+	chunkJobConcurrency   = 4
+)
+
+// chunkSpec describes one overlapping slice of the input audio, on disk and
+// ready to upload.
+type chunkSpec struct {
+	Index  int
+	Path   string
+	Offset time.Duration
+}
+
+// chunkTranscript is the Transcribe output for a single chunk, still
+// positioned relative to the chunk's own start rather than the full input.
+type chunkTranscript struct {
+	Offset time.Duration
+	Output asrOutput
+}
+
+// probeDuration returns the duration of the media file at path using
+// ffprobe.
+func probeDuration(path string) (time.Duration, error) {
+	cmd := exec.Command("ffprobe", "-v", "error", "-show_entries", "format=duration",
+		"-of", "default=noprint_wrappers=1:nokey=1", path)
+
+	out, err := cmd.Output()
+	if err != nil {
+		return 0, fmt.Errorf("error running ffprobe: %w", err)
+	}
+
+	seconds, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing ffprobe duration: %w", err)
+	}
+
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// formatSeconds renders d as a decimal seconds string suitable for ffmpeg's
+// -ss/-t flags.
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+// splitIntoChunks probes inputPath's duration and splits it into overlapping
+// chunks of chunkDuration (with overlap seconds shared between consecutive
+// chunks), writing each chunk into outDir with ffmpeg -ss/-t -c copy so no
+// re-encoding is needed.
+func splitIntoChunks(inputPath, outDir string, chunkDuration, overlap time.Duration) ([]chunkSpec, error) {
+	totalDuration, err := probeDuration(inputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	step := chunkDuration - overlap
+	if step <= 0 {
+		return nil, fmt.Errorf("chunk duration %s must exceed overlap %s", chunkDuration, overlap)
+	}
+
+	ext := filepath.Ext(inputPath)
+	var chunks []chunkSpec
+
+	for offset, index := time.Duration(0), 0; offset < totalDuration; offset, index = offset+step, index+1 {
+		duration := chunkDuration
+		if offset+duration > totalDuration {
+			duration = totalDuration - offset
+		}
+
+		chunkPath := filepath.Join(outDir, fmt.Sprintf("chunk_%03d%s", index, ext))
+		cmd := exec.Command("ffmpeg", "-y",
+			"-ss", formatSeconds(offset),
+			"-t", formatSeconds(duration),
+			"-i", inputPath,
+			"-c", "copy",
+			chunkPath,
+		)
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("error splitting chunk %d: %w", index, err)
+		}
+
+		chunks = append(chunks, chunkSpec{Index: index, Path: chunkPath, Offset: offset})
+
+		if offset+duration >= totalDuration {
+			break
+		}
+	}
+
+	return chunks, nil
+}
+
+// runChunkedTranscription splits inputFile into overlapping chunks, dispatches
+// a bounded-concurrency Transcribe job per chunk, and merges the results back
+// into a single asrOutput positioned against the original input's timeline.
+func runChunkedTranscription(ctx context.Context, transcribeClient *transcribe.Client, s3Client *s3.Client, bucket, keyPrefix, inputFile, jobNamePrefix string) (asrOutput, error) {
+	outDir, err := os.MkdirTemp("", "transcribe-chunks-")
+	if err != nil {
+		return asrOutput{}, fmt.Errorf("error creating chunk directory: %w", err)
+	}
+	defer os.RemoveAll(outDir)
+
+	fmt.Printf("input exceeds %s, splitting into chunks...\n", defaultChunkThreshold)
+	chunks, err := splitIntoChunks(inputFile, outDir, defaultChunkDuration, defaultChunkOverlap)
+	if err != nil {
+		return asrOutput{}, err
+	}
+
+	fmt.Printf("transcribing %d chunks (%d concurrent)...\n", len(chunks), chunkJobConcurrency)
+	transcripts, err := transcribeChunks(ctx, transcribeClient, s3Client, bucket, keyPrefix, jobNamePrefix, chunks)
+	if err != nil {
+		return asrOutput{}, err
+	}
+
+	return mergeChunkTranscripts(transcripts, defaultChunkOverlap), nil
+}
+
+// transcribeChunks uploads and transcribes each chunk, bounded to
+// chunkJobConcurrency concurrent jobs at a time.
+func transcribeChunks(ctx context.Context, transcribeClient *transcribe.Client, s3Client *s3.Client, bucket, keyPrefix, jobNamePrefix string, chunks []chunkSpec) ([]chunkTranscript, error) {
+	results := make([]chunkTranscript, len(chunks))
+	sem := make(chan struct{}, chunkJobConcurrency)
+	errCh := make(chan error, len(chunks))
+	var wg sync.WaitGroup
+
+	for _, c := range chunks {
+		c := c
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			output, err := transcribeChunk(ctx, transcribeClient, s3Client, bucket, keyPrefix, jobNamePrefix, c)
+			if err != nil {
+				select {
+				case errCh <- fmt.Errorf("chunk %d: %w", c.Index, err):
+				default:
+				}
+				return
+			}
+
+			results[c.Index] = chunkTranscript{Offset: c.Offset, Output: output}
+		}()
+	}
+
+	wg.Wait()
+	close(errCh)
+
+	if err, ok := <-errCh; ok {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// transcribeChunk uploads a single chunk, runs it through Transcribe, and
+// returns its parsed output once the job completes.
+func transcribeChunk(ctx context.Context, transcribeClient *transcribe.Client, s3Client *s3.Client, bucket, keyPrefix, jobNamePrefix string, c chunkSpec) (asrOutput, error) {
+	key := fmt.Sprintf("%schunks/chunk_%03d%s", keyPrefix, c.Index, filepath.Ext(c.Path))
+	jobName := fmt.Sprintf("%s_chunk%03d", jobNamePrefix, c.Index)
+	jsonKey := fmt.Sprintf("%schunks/chunk_%03d.json", keyPrefix, c.Index)
+
+	file, err := os.Open(c.Path)
+	if err != nil {
+		return asrOutput{}, fmt.Errorf("error opening chunk file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return asrOutput{}, fmt.Errorf("error reading chunk file: %w", err)
+	}
+
+	if err := newUploader(s3Client).Upload(ctx, bucket, key, file, info.Size()); err != nil {
+		return asrOutput{}, fmt.Errorf("error uploading chunk: %w", err)
+	}
+
+	_, err = transcribeClient.StartTranscriptionJob(ctx, &transcribe.StartTranscriptionJobInput{
+		TranscriptionJobName: aws.String(jobName),
+		Media: &types.Media{
+			MediaFileUri: aws.String(fmt.Sprintf("s3://%s/%s", bucket, key)),
+		},
+		LanguageCode:     types.LanguageCodeEnUs,
+		OutputBucketName: aws.String(bucket),
+		OutputKey:        aws.String(jsonKey),
+	})
+	if err != nil {
+		return asrOutput{}, fmt.Errorf("error starting transcription job: %w", err)
+	}
+
+	for {
+		statusOutput, err := transcribeClient.GetTranscriptionJob(ctx, &transcribe.GetTranscriptionJobInput{
+			TranscriptionJobName: aws.String(jobName),
+		})
+		if err != nil {
+			return asrOutput{}, fmt.Errorf("error checking job status: %w", err)
+		}
+
+		switch statusOutput.TranscriptionJob.TranscriptionJobStatus {
+		case types.TranscriptionJobStatusCompleted:
+			return downloadTranscript(ctx, s3Client, bucket, jsonKey)
+		case types.TranscriptionJobStatusFailed:
+			return asrOutput{}, fmt.Errorf("transcription job %s failed", jobName)
+		}
+
+		time.Sleep(5 * time.Second)
+	}
+}
+
+// downloadTranscript fetches and parses a Transcribe results JSON file from
+// S3.
+func downloadTranscript(ctx context.Context, s3Client *s3.Client, bucket, key string) (asrOutput, error) {
+	result, err := s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return asrOutput{}, fmt.Errorf("error downloading transcript: %w", err)
+	}
+
+	data, err := io.ReadAll(result.Body)
+	if err != nil {
+		return asrOutput{}, fmt.Errorf("error reading transcript data: %w", err)
+	}
+
+	var output asrOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return asrOutput{}, fmt.Errorf("error parsing transcript: %w", err)
+	}
+
+	return output, nil
+}
+
+// mergeChunkTranscripts stitches per-chunk Transcribe output back into a
+// single asrOutput, offsetting each item's timestamps by its chunk's base
+// offset and dropping items from the start of each chunk (other than the
+// first) that fall within the overlap window shared with the previous chunk.
+// Punctuation items carry no timestamp of their own (see asrItem), so a
+// punctuation item is dropped alongside the preceding word whenever that
+// word was itself dropped as part of the overlap, keeping punctuation from
+// reattaching to the wrong word across a chunk boundary.
+func mergeChunkTranscripts(chunks []chunkTranscript, overlap time.Duration) asrOutput {
+	sorted := make([]chunkTranscript, len(chunks))
+	copy(sorted, chunks)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Offset < sorted[j].Offset })
+
+	var merged asrOutput
+
+	for i, c := range sorted {
+		droppedPrev := false
+
+		for _, item := range c.Output.Results.Items {
+			drop := false
+			if i > 0 {
+				switch {
+				case item.StartTime != "":
+					if start, err := strconv.ParseFloat(item.StartTime, 64); err == nil {
+						drop = time.Duration(start*float64(time.Second)) < overlap
+					}
+				default:
+					drop = droppedPrev
+				}
+			}
+			droppedPrev = drop
+
+			if drop {
+				continue
+			}
+
+			offsetItem := item
+			if item.StartTime != "" {
+				offsetItem.StartTime = formatSeconds(offsetTimestamp(item.StartTime, c.Offset))
+			}
+			if item.EndTime != "" {
+				offsetItem.EndTime = formatSeconds(offsetTimestamp(item.EndTime, c.Offset))
+			}
+
+			merged.Results.Items = append(merged.Results.Items, offsetItem)
+		}
+	}
+
+	merged.Results.Transcripts = []asrTranscript{{Transcript: joinTranscriptText(merged.Results.Items)}}
+
+	return merged
+}
+
+// offsetTimestamp parses a Transcribe timestamp string (decimal seconds) and
+// adds offset, returning offset unchanged if it can't be parsed.
+func offsetTimestamp(timestamp string, offset time.Duration) time.Duration {
+	seconds, err := strconv.ParseFloat(timestamp, 64)
+	if err != nil {
+		return offset
+	}
+	return time.Duration(seconds*float64(time.Second)) + offset
+}
+
+// joinTranscriptText reconstructs a flat transcript string from word and
+// punctuation items, the same way Transcribe's own transcripts[0].transcript
+// is built: a space before each word, punctuation attached to the preceding
+// word.
+func joinTranscriptText(items []asrItem) string {
+	var b strings.Builder
+	for _, item := range items {
+		if len(item.Alternatives) == 0 {
+			continue
+		}
+
+		content := item.Alternatives[0].Content
+		if item.Type == "punctuation" {
+			b.WriteString(content)
+			continue
+		}
+
+		if b.Len() > 0 {
+			b.WriteString(" ")
+		}
+		b.WriteString(content)
+	}
+	return b.String()
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeChunkTranscripts(t *testing.T) {
+	overlap := 5 * time.Second
+
+	var chunk0 asrOutput
+	chunk0.Results.Items = []asrItem{
+		{Type: "pronunciation", StartTime: "0.000", EndTime: "0.500", Alternatives: []asrAlternative{{Content: "hello"}}},
+		{Type: "pronunciation", StartTime: "25.000", EndTime: "25.500", Alternatives: []asrAlternative{{Content: "world"}}},
+	}
+
+	var chunk1 asrOutput
+	chunk1.Results.Items = []asrItem{
+		// falls inside the 5s overlap shared with chunk0 and should be dropped
+		{Type: "pronunciation", StartTime: "2.000", EndTime: "2.500", Alternatives: []asrAlternative{{Content: "dup"}}},
+		{Type: "pronunciation", StartTime: "6.000", EndTime: "6.500", Alternatives: []asrAlternative{{Content: "foo"}}},
+		{Type: "punctuation", Alternatives: []asrAlternative{{Content: "."}}},
+	}
+
+	merged := mergeChunkTranscripts([]chunkTranscript{
+		{Offset: 30 * time.Second, Output: chunk1}, // passed out of order on purpose
+		{Offset: 0, Output: chunk0},
+	}, overlap)
+
+	items := merged.Results.Items
+	if len(items) != 4 {
+		t.Fatalf("expected 4 items after dropping the overlapping one, got %d: %+v", len(items), items)
+	}
+
+	if got := items[0].Alternatives[0].Content; got != "hello" {
+		t.Errorf("expected first item %q, got %q", "hello", got)
+	}
+	if got := items[1].Alternatives[0].Content; got != "world" {
+		t.Errorf("expected second item %q, got %q", "world", got)
+	}
+
+	foo := items[2]
+	if foo.Alternatives[0].Content != "foo" {
+		t.Fatalf("expected third item %q, got %q", "foo", foo.Alternatives[0].Content)
+	}
+	if foo.StartTime != "36.000" || foo.EndTime != "36.500" {
+		t.Errorf("expected chunk1's offset (30s) applied to foo's times, got start=%q end=%q", foo.StartTime, foo.EndTime)
+	}
+
+	period := items[3]
+	if period.Alternatives[0].Content != "." {
+		t.Fatalf("expected fourth item %q, got %q", ".", period.Alternatives[0].Content)
+	}
+
+	wantText := "hello world foo."
+	if got := merged.Results.Transcripts[0].Transcript; got != wantText {
+		t.Errorf("expected merged transcript %q, got %q", wantText, got)
+	}
+}
+
+func TestMergeChunkTranscriptsDropsPunctuationAttachedToDroppedWord(t *testing.T) {
+	overlap := 5 * time.Second
+
+	var chunk0 asrOutput
+	chunk0.Results.Items = []asrItem{
+		{Type: "pronunciation", StartTime: "0.000", EndTime: "0.500", Alternatives: []asrAlternative{{Content: "hello"}}},
+	}
+
+	var chunk1 asrOutput
+	chunk1.Results.Items = []asrItem{
+		// "dup" and its trailing punctuation both fall inside the overlap and
+		// should be dropped together, not just the word
+		{Type: "pronunciation", StartTime: "1.000", EndTime: "1.500", Alternatives: []asrAlternative{{Content: "dup"}}},
+		{Type: "punctuation", Alternatives: []asrAlternative{{Content: "."}}},
+		{Type: "pronunciation", StartTime: "6.000", EndTime: "6.500", Alternatives: []asrAlternative{{Content: "kept"}}},
+		{Type: "punctuation", Alternatives: []asrAlternative{{Content: "!"}}},
+	}
+
+	merged := mergeChunkTranscripts([]chunkTranscript{
+		{Offset: 0, Output: chunk0},
+		{Offset: 30 * time.Second, Output: chunk1},
+	}, overlap)
+
+	items := merged.Results.Items
+	if len(items) != 3 {
+		t.Fatalf("expected 3 items (hello, kept, !), got %d: %+v", len(items), items)
+	}
+
+	wantText := "hello kept!"
+	if got := merged.Results.Transcripts[0].Transcript; got != wantText {
+		t.Errorf("expected merged transcript %q, got %q", wantText, got)
+	}
+}
+
+func TestMergeChunkTranscriptsEmpty(t *testing.T) {
+	merged := mergeChunkTranscripts(nil, 5*time.Second)
+	if len(merged.Results.Items) != 0 {
+		t.Fatalf("expected no items for empty input, got %d", len(merged.Results.Items))
+	}
+	if merged.Results.Transcripts[0].Transcript != "" {
+		t.Errorf("expected empty transcript, got %q", merged.Results.Transcripts[0].Transcript)
+	}
+}
@@ -2,220 +2,260 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/aws/aws-sdk-go-v2/service/transcribe"
-	"github.com/aws/aws-sdk-go-v2/service/transcribe/types"
 	"github.com/joho/godotenv"
 )
 
+// asrTranscript is a single flattened transcript string from a Transcribe
+// results payload.
+type asrTranscript struct {
+	Transcript string `json:"transcript"`
+}
+
+// asrAlternative is one candidate reading of an asrItem.
+type asrAlternative struct {
+	Content    string `json:"content"`
+	Confidence string `json:"confidence,omitempty"`
+}
+
+// asrItem is a single word or punctuation mark from a Transcribe results
+// payload. Punctuation items carry no start_time/end_time.
+type asrItem struct {
+	StartTime    string           `json:"start_time,omitempty"`
+	EndTime      string           `json:"end_time,omitempty"`
+	Type         string           `json:"type"`
+	Alternatives []asrAlternative `json:"alternatives"`
+}
+
 // asrOutput represents the structure of our JSON file
 type asrOutput struct {
 	Results struct {
-		Transcripts []struct {
-			Transcript string `json:"transcript"`
-		} `json:"transcripts"`
+		Transcripts []asrTranscript `json:"transcripts"`
+		Items       []asrItem       `json:"items"`
 	} `json:"results"`
 }
 
+// sanitizeKeyComponentPattern matches runs of characters that aren't safe to
+// use unescaped in an S3 key or Transcribe job name.
+var sanitizeKeyComponentPattern = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// sanitizeKeyComponent strips a video title down to characters that are safe
+// to embed in an S3 key / job name.
+func sanitizeKeyComponent(s string) string {
+	return strings.Trim(sanitizeKeyComponentPattern.ReplaceAllString(s, "_"), "_")
+}
+
 // printProgress prints a loading indicator with the given message
 func printProgress(message string, progress int) {
 	// clear line
 	fmt.Print("\r\033[K")
-	
+
 	// create progress bar
 	barWidth := 50
 	filled := progress * barWidth / 100
 	bar := strings.Repeat("=", filled) + strings.Repeat(" ", barWidth-filled)
-	
+
 	// print progress bar and message
 	fmt.Printf("\r%s [%s] %d%%", message, bar, progress)
 }
 
 func main() {
-	// load .env file
-	if err := godotenv.Load(); err != nil {
-		fmt.Printf("error loading .env file: %v\n", err)
-		os.Exit(1)
-	}
+	youtubeFormatFlag := flag.String("youtube-format", "", "youtube itag to download instead of auto-selecting the highest-bitrate audio-only format")
+	backendFlag := flag.String("backend", "aws", "transcription backend: aws, whisper-local, or whisper-api")
+	cleanupFlag := flag.Bool("cleanup", false, "delete the uploaded source audio from s3 after transcription succeeds (aws backend only)")
+	presignTTLFlag := flag.Duration("presign-ttl", defaultPresignTTL, "how long presigned output URLs stay valid (aws backend only)")
+	srtFlag := flag.Bool("srt", false, "also write an .srt subtitle file")
+	vttFlag := flag.Bool("vtt", false, "also write a .vtt subtitle file")
+	flag.Parse()
 
-	if len(os.Args) != 2 {
-		fmt.Println("usage: transcribe <input_file>")
+	if flag.NArg() != 1 {
+		fmt.Println("usage: transcribe [--backend aws|whisper-local|whisper-api] [--youtube-format itag] [--srt] [--vtt] <input_file_or_youtube_url>")
 		os.Exit(1)
 	}
 
-	inputFile := os.Args[1]
-	if _, err := os.Stat(inputFile); os.IsNotExist(err) {
-		fmt.Printf("error: file %s does not exist\n", inputFile)
-		os.Exit(1)
+	// the whisper-local/whisper-api backends need no AWS credentials, so only
+	// the aws backend requires a .env file to be present
+	if *backendFlag == "aws" {
+		if err := godotenv.Load(); err != nil {
+			fmt.Printf("error loading .env file: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
-	// get input file directory
-	inputDir := filepath.Dir(inputFile)
-	
-	// generate unique output filenames
-	baseName := filepath.Base(inputFile)
-	ext := filepath.Ext(baseName)
-	nameWithoutExt := strings.TrimSuffix(baseName, ext)
+	inputArg := flag.Arg(0)
 	timestamp := time.Now().Format("20060102_150405")
-	
-	// create output filenames in same directory as input
-	audioFile := filepath.Join(inputDir, fmt.Sprintf("%s_%s.mp3", nameWithoutExt, timestamp))
-	transcriptFile := filepath.Join(inputDir, fmt.Sprintf("%s_%s.txt", nameWithoutExt, timestamp))
-	jsonFile := filepath.Join(inputDir, fmt.Sprintf("%s_%s.json", nameWithoutExt, timestamp))
 
-	// convert video to audio if needed
-	if strings.ToLower(ext) == ".mp4" {
-		fmt.Printf("converting %s to audio...\n", inputFile)
-		cmd := exec.Command("ffmpeg", "-i", inputFile, "-vn", "-acodec", "libmp3lame", audioFile)
-		if err := cmd.Run(); err != nil {
-			fmt.Printf("error converting video to audio: %v\n", err)
+	var (
+		inputFile      string
+		inputDir       string
+		nameWithoutExt string
+	)
+
+	if isYouTubeInput(inputArg) {
+		itag, err := parseItag(*youtubeFormatFlag)
+		if err != nil {
+			fmt.Println(err)
 			os.Exit(1)
 		}
-		inputFile = audioFile
-	}
 
-	// initialize aws config with credentials from env
-	cfg, err := config.LoadDefaultConfig(context.TODO(),
-		config.WithRegion(os.Getenv("AWS_REGION")),
-		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
-			os.Getenv("AWS_ACCESS_KEY"),
-			os.Getenv("AWS_SECRET_ACCESS_KEY"),
-			"",
-		)),
-	)
-	if err != nil {
-		fmt.Printf("error loading aws config: %v\n", err)
-		os.Exit(1)
-	}
+		fmt.Printf("resolving youtube video %s...\n", inputArg)
+		yt, err := downloadYouTubeAudio(context.TODO(), inputArg, itag)
+		if err != nil {
+			fmt.Printf("error downloading youtube audio: %v\n", err)
+			os.Exit(1)
+		}
 
-	// create s3 client
-	s3Client := s3.NewFromConfig(cfg)
-	bucketName := "vault" // use just the bucket name without arn prefix
+		inputDir = "."
+		nameWithoutExt = fmt.Sprintf("%s_%s", sanitizeKeyComponent(yt.Title), yt.VideoID)
+		inputFile = fmt.Sprintf("%s_%s.mp3", nameWithoutExt, timestamp)
 
-	// upload audio file to s3
-	fmt.Printf("uploading %s to s3...\n", inputFile)
-	file, err := os.Open(inputFile)
-	if err != nil {
-		fmt.Printf("error opening audio file: %v\n", err)
-		os.Exit(1)
-	}
-	defer file.Close()
+		out, err := os.Create(inputFile)
+		if err != nil {
+			fmt.Printf("error creating audio file: %v\n", err)
+			os.Exit(1)
+		}
+		defer out.Close()
 
-	_, err = s3Client.PutObject(context.TODO(), &s3.PutObjectInput{
-		Bucket: aws.String(bucketName),
-		Key:    aws.String(inputFile),
-		Body:   file,
-	})
-	if err != nil {
-		fmt.Printf("error uploading to s3: %v\n", err)
-		os.Exit(1)
-	}
+		if _, err := io.Copy(out, yt.Reader); err != nil {
+			fmt.Printf("error downloading youtube audio: %v\n", err)
+			os.Exit(1)
+		}
 
-	// create transcribe client
-	transcribeClient := transcribe.NewFromConfig(cfg)
+		defer os.Remove(inputFile)
+	} else {
+		inputFile = inputArg
+		if _, err := os.Stat(inputFile); os.IsNotExist(err) {
+			fmt.Printf("error: file %s does not exist\n", inputFile)
+			os.Exit(1)
+		}
 
-	// start transcription job
-	jobName := fmt.Sprintf("%s_%s", nameWithoutExt, timestamp)
-	input := &transcribe.StartTranscriptionJobInput{
-		TranscriptionJobName: aws.String(jobName),
-		Media: &types.Media{
-			MediaFileUri: aws.String(fmt.Sprintf("s3://%s/%s", bucketName, inputFile)),
-		},
-		LanguageCode: types.LanguageCodeEnUs,
-		OutputBucketName: aws.String(bucketName),
-		OutputKey: aws.String(jsonFile),
-	}
+		// get input file directory
+		inputDir = filepath.Dir(inputFile)
 
-	fmt.Printf("starting transcription job %s...\n", jobName)
-	_, err = transcribeClient.StartTranscriptionJob(context.TODO(), input)
-	if err != nil {
-		fmt.Printf("error starting transcription job: %v\n", err)
-		os.Exit(1)
-	}
+		// generate unique output filenames
+		baseName := filepath.Base(inputFile)
+		ext := filepath.Ext(baseName)
+		nameWithoutExt = strings.TrimSuffix(baseName, ext)
 
-	// wait for job completion with progress indicator
-	fmt.Println("waiting for transcription to complete...")
-	startTime := time.Now()
-	for {
-		output, err := transcribeClient.GetTranscriptionJob(context.TODO(), &transcribe.GetTranscriptionJobInput{
-			TranscriptionJobName: aws.String(jobName),
-		})
-		if err != nil {
-			fmt.Printf("\nerror checking job status: %v\n", err)
-			os.Exit(1)
+		// convert video to audio if needed
+		if strings.ToLower(ext) == ".mp4" {
+			audioFile := filepath.Join(inputDir, fmt.Sprintf("%s_%s.mp3", nameWithoutExt, timestamp))
+			fmt.Printf("converting %s to audio...\n", inputFile)
+			cmd := exec.Command("ffmpeg", "-i", inputFile, "-vn", "-acodec", "libmp3lame", audioFile)
+			if err := cmd.Run(); err != nil {
+				fmt.Printf("error converting video to audio: %v\n", err)
+				os.Exit(1)
+			}
+			inputFile = audioFile
 		}
+	}
 
-		// calculate progress based on elapsed time (rough estimate)
-		elapsed := time.Since(startTime)
-		progress := int(elapsed.Seconds() / 2) // assume 2 seconds per percent
-		if progress > 100 {
-			progress = 100
-		}
+	// create output filenames in same directory as input
+	transcriptFile := filepath.Join(inputDir, fmt.Sprintf("%s_%s.txt", nameWithoutExt, timestamp))
+	jsonFile := filepath.Join(inputDir, fmt.Sprintf("%s_%s.json", nameWithoutExt, timestamp))
+	srtFile := filepath.Join(inputDir, fmt.Sprintf("%s_%s.srt", nameWithoutExt, timestamp))
+	vttFile := filepath.Join(inputDir, fmt.Sprintf("%s_%s.vtt", nameWithoutExt, timestamp))
 
-		// show progress
-		printProgress("Transcribing...", progress)
+	jobName := fmt.Sprintf("%s_%s", nameWithoutExt, timestamp)
 
-		if output.TranscriptionJob.TranscriptionJobStatus == types.TranscriptionJobStatusCompleted {
-			fmt.Println("\nTranscription completed!")
-			break
-		} else if output.TranscriptionJob.TranscriptionJobStatus == types.TranscriptionJobStatusFailed {
-			fmt.Println("\nTranscription job failed")
+	var transcriber Transcriber
+	switch *backendFlag {
+	case "aws":
+		cfg, err := config.LoadDefaultConfig(context.TODO(),
+			config.WithRegion(os.Getenv("AWS_REGION")),
+			config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
+				os.Getenv("AWS_ACCESS_KEY"),
+				os.Getenv("AWS_SECRET_ACCESS_KEY"),
+				"",
+			)),
+		)
+		if err != nil {
+			fmt.Printf("error loading aws config: %v\n", err)
 			os.Exit(1)
 		}
-
-		time.Sleep(1 * time.Second)
+		bucketName := os.Getenv("TRANSCRIBE_BUCKET")
+		if bucketName == "" {
+			bucketName = "vault"
+		}
+		transcriber = newAWSTranscriber(cfg, bucketName, jobName)
+	case "whisper-local":
+		transcriber = newWhisperLocalTranscriber()
+	case "whisper-api":
+		transcriber = newWhisperAPITranscriber()
+	default:
+		fmt.Printf("error: unknown backend %q (want aws, whisper-local, or whisper-api)\n", *backendFlag)
+		os.Exit(1)
 	}
 
-	// download and process the transcript
-	fmt.Println("processing transcript...")
-	
-	// download the json file from s3
-	result, err := s3Client.GetObject(context.TODO(), &s3.GetObjectInput{
-		Bucket: aws.String(bucketName),
-		Key:    aws.String(jsonFile),
-	})
+	transcript, err := transcriber.Transcribe(context.TODO(), inputFile)
 	if err != nil {
-		fmt.Printf("error downloading transcript: %v\n", err)
+		fmt.Printf("error transcribing audio: %v\n", err)
 		os.Exit(1)
 	}
 
-	// read the json data
-	jsonData, err := io.ReadAll(result.Body)
-	if err != nil {
-		fmt.Printf("error reading transcript data: %v\n", err)
+	if len(transcript.Segments) == 0 {
+		fmt.Println("no transcript segments produced")
 		os.Exit(1)
 	}
 
-	// parse the json data
-	var output asrOutput
-	if err := json.Unmarshal(jsonData, &output); err != nil {
-		fmt.Printf("error parsing transcript: %v\n", err)
+	if err := writeTranscriptJSON(transcript, jsonFile); err != nil {
+		fmt.Println(err)
 		os.Exit(1)
 	}
 
-	// check if we have any transcripts
-	if len(output.Results.Transcripts) == 0 {
-		fmt.Println("no transcripts found in the file")
+	if err := writeTranscriptText(transcript, transcriptFile); err != nil {
+		fmt.Println(err)
 		os.Exit(1)
 	}
 
-	// write the transcript to a text file
-	err = os.WriteFile(transcriptFile, []byte(output.Results.Transcripts[0].Transcript), 0644)
-	if err != nil {
-		fmt.Printf("error writing transcript file: %v\n", err)
-		os.Exit(1)
+	if *srtFlag || *vttFlag {
+		cues := groupIntoCues(transcript.Segments, defaultMaxCharsPerLine, defaultMaxCueDuration)
+
+		if *srtFlag {
+			if err := writeSRT(cues, srtFile); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+
+		if *vttFlag {
+			if err := writeVTT(cues, vttFile); err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		}
+	}
+
+	if awsTranscriber, ok := transcriber.(*awsTranscriber); ok {
+		audioURL, jsonURL, txtURL, err := awsTranscriber.PresignOutputs(context.TODO(), jsonFile, transcriptFile, *presignTTLFlag)
+		if err != nil {
+			fmt.Printf("error generating presigned urls: %v\n", err)
+			os.Exit(1)
+		}
+
+		if audioURL != "" && !*cleanupFlag {
+			fmt.Printf("audio: %s\n", audioURL)
+		}
+		fmt.Printf("json: %s\n", jsonURL)
+		fmt.Printf("txt: %s\n", txtURL)
+
+		if *cleanupFlag {
+			if err := awsTranscriber.Cleanup(context.TODO()); err != nil {
+				fmt.Printf("error cleaning up source audio: %v\n", err)
+				os.Exit(1)
+			}
+		}
 	}
 
 	fmt.Printf("transcription completed successfully. output saved to %s\n", transcriptFile)
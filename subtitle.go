@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	defaultMaxCharsPerLine = 42
+	defaultMaxCueDuration  = 5 * time.Second
+)
+
+// caption is a single subtitle cue: a time range and the text shown during
+// it.
+type caption struct {
+	Start time.Duration
+	End   time.Duration
+	Text  string
+}
+
+// groupIntoCues groups transcript segments into subtitle cues, starting a new
+// cue whenever adding the next segment would push the cue's text past
+// maxChars or its duration past maxDuration, and otherwise preferring to
+// break right after sentence-ending punctuation.
+func groupIntoCues(segments []TranscriptSegment, maxChars int, maxDuration time.Duration) []caption {
+	var cues []caption
+	var current caption
+	var text strings.Builder
+
+	flush := func() {
+		if text.Len() == 0 {
+			return
+		}
+		current.Text = text.String()
+		cues = append(cues, current)
+		current = caption{}
+		text.Reset()
+	}
+
+	for _, seg := range segments {
+		if seg.Text == "" {
+			continue
+		}
+
+		candidateLen := text.Len() + len(seg.Text)
+		if text.Len() > 0 {
+			candidateLen++ // joining space
+		}
+
+		exceedsChars := text.Len() > 0 && candidateLen > maxChars
+		exceedsDuration := text.Len() > 0 && seg.End-current.Start > maxDuration
+
+		if exceedsChars || exceedsDuration {
+			flush()
+		}
+
+		if text.Len() == 0 {
+			current.Start = seg.Start
+		} else {
+			text.WriteString(" ")
+		}
+		text.WriteString(seg.Text)
+		current.End = seg.End
+
+		if endsSentence(seg.Text) {
+			flush()
+		}
+	}
+	flush()
+
+	return cues
+}
+
+// endsSentence reports whether text ends with sentence-closing punctuation,
+// a good place to prefer breaking a cue.
+func endsSentence(text string) bool {
+	if text == "" {
+		return false
+	}
+	switch text[len(text)-1] {
+	case '.', '?', '!':
+		return true
+	default:
+		return false
+	}
+}
+
+// writeSRT writes cues to path in SubRip (.srt) format.
+func writeSRT(cues []caption, path string) error {
+	var b strings.Builder
+	for i, c := range cues {
+		fmt.Fprintf(&b, "%d\n", i+1)
+		fmt.Fprintf(&b, "%s --> %s\n", formatTimestamp(c.Start, ","), formatTimestamp(c.End, ","))
+		fmt.Fprintf(&b, "%s\n\n", c.Text)
+	}
+
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("error writing srt file: %w", err)
+	}
+	return nil
+}
+
+// writeVTT writes cues to path in WebVTT (.vtt) format.
+func writeVTT(cues []caption, path string) error {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for _, c := range cues {
+		fmt.Fprintf(&b, "%s --> %s\n", formatTimestamp(c.Start, "."), formatTimestamp(c.End, "."))
+		fmt.Fprintf(&b, "%s\n\n", c.Text)
+	}
+
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("error writing vtt file: %w", err)
+	}
+	return nil
+}
+
+// formatTimestamp renders d as HH:MM:SS<fractionSep>mmm, the shared layout
+// used by both .srt (",") and .vtt (".") timestamps.
+func formatTimestamp(d time.Duration, fractionSep string) string {
+	if d < 0 {
+		d = 0
+	}
+
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	minutes := d / time.Minute
+	d -= minutes * time.Minute
+	seconds := d / time.Second
+	d -= seconds * time.Second
+	millis := d / time.Millisecond
+
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", hours, minutes, seconds, fractionSep, millis)
+}
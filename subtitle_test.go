@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// fixtureTranscribeJSON is a minimal Transcribe results payload covering two
+// short sentences, used to verify cue grouping and timing end-to-end.
+const fixtureTranscribeJSON = `{
+  "results": {
+    "transcripts": [{"transcript": "Hi there friend. Bye now."}],
+    "items": [
+      {"start_time": "0.000", "end_time": "0.300", "type": "pronunciation", "alternatives": [{"content": "Hi"}]},
+      {"start_time": "0.300", "end_time": "0.700", "type": "pronunciation", "alternatives": [{"content": "there"}]},
+      {"start_time": "0.700", "end_time": "1.200", "type": "pronunciation", "alternatives": [{"content": "friend"}]},
+      {"type": "punctuation", "alternatives": [{"content": "."}]},
+      {"start_time": "2.000", "end_time": "2.300", "type": "pronunciation", "alternatives": [{"content": "Bye"}]},
+      {"start_time": "2.300", "end_time": "2.600", "type": "pronunciation", "alternatives": [{"content": "now"}]},
+      {"type": "punctuation", "alternatives": [{"content": "."}]}
+    ]
+  }
+}`
+
+func TestGroupIntoCuesFromFixtureTranscript(t *testing.T) {
+	var output asrOutput
+	if err := json.Unmarshal([]byte(fixtureTranscribeJSON), &output); err != nil {
+		t.Fatalf("error parsing fixture: %v", err)
+	}
+
+	transcript := asrOutputToTranscript(output)
+	cues := groupIntoCues(transcript.Segments, defaultMaxCharsPerLine, defaultMaxCueDuration)
+
+	if len(cues) != 2 {
+		t.Fatalf("expected 2 cues (one per sentence), got %d: %+v", len(cues), cues)
+	}
+
+	first := cues[0]
+	if first.Text != "Hi there friend." {
+		t.Errorf("unexpected first cue text: %q", first.Text)
+	}
+	if first.Start != 0 {
+		t.Errorf("expected first cue to start at 0, got %s", first.Start)
+	}
+	if first.End != 1200*time.Millisecond {
+		t.Errorf("expected first cue to end at 1.2s, got %s", first.End)
+	}
+
+	second := cues[1]
+	if second.Text != "Bye now." {
+		t.Errorf("unexpected second cue text: %q", second.Text)
+	}
+	if second.Start != 2*time.Second {
+		t.Errorf("expected second cue to start at 2s, got %s", second.Start)
+	}
+	if second.End != 2600*time.Millisecond {
+		t.Errorf("expected second cue to end at 2.6s, got %s", second.End)
+	}
+}
+
+func TestFormatTimestamp(t *testing.T) {
+	cases := []struct {
+		d    time.Duration
+		sep  string
+		want string
+	}{
+		{0, ",", "00:00:00,000"},
+		{3*time.Second + 500*time.Millisecond, ".", "00:00:03.500"},
+		{time.Hour + 2*time.Minute + 3*time.Second + 4*time.Millisecond, ",", "01:02:03,004"},
+		{-time.Second, ",", "00:00:00,000"},
+	}
+
+	for _, c := range cases {
+		if got := formatTimestamp(c.d, c.sep); got != c.want {
+			t.Errorf("formatTimestamp(%s, %q) = %q, want %q", c.d, c.sep, got, c.want)
+		}
+	}
+}
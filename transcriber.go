@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// TranscriptSegment is a single timed span of a transcript, roughly
+// word-to-sentence granularity depending on the backend that produced it.
+type TranscriptSegment struct {
+	Text       string        `json:"text"`
+	Start      time.Duration `json:"start"`
+	End        time.Duration `json:"end"`
+	Confidence float64       `json:"confidence,omitempty"`
+}
+
+// Transcript is the backend-agnostic result of transcribing an audio file.
+type Transcript struct {
+	Segments []TranscriptSegment `json:"segments"`
+}
+
+// Text flattens the transcript's segments into a single space-separated
+// string, for the plain-text writer.
+func (t Transcript) Text() string {
+	texts := make([]string, len(t.Segments))
+	for i, seg := range t.Segments {
+		texts[i] = seg.Text
+	}
+	return strings.Join(texts, " ")
+}
+
+// Transcriber transcribes a local audio file into a Transcript. Backends
+// (AWS Transcribe, whisper.cpp, the OpenAI Whisper API) each implement this
+// the same way so main doesn't need to know which one is in use.
+type Transcriber interface {
+	Transcribe(ctx context.Context, audioPath string) (Transcript, error)
+}
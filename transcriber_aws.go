@@ -0,0 +1,284 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/transcribe"
+	"github.com/aws/aws-sdk-go-v2/service/transcribe/types"
+)
+
+// defaultPresignTTL is how long presigned output URLs stay valid.
+const defaultPresignTTL = time.Hour
+
+// awsTranscriber transcribes audio with AWS Transcribe: upload to S3, start a
+// transcription job (splitting into chunks first for long-form input), poll
+// for completion, and parse the resulting JSON. All S3 objects for a run are
+// grouped under keyPrefix so inputs, outputs, and chunk artifacts stay
+// together.
+type awsTranscriber struct {
+	s3Client         *s3.Client
+	transcribeClient *transcribe.Client
+	bucket           string
+	jobName          string
+	keyPrefix        string
+	audioKey         string // set once Transcribe has uploaded the source audio
+	chunked          bool   // set once Transcribe has taken the chunked path
+}
+
+// newAWSTranscriber builds an awsTranscriber from an AWS config. All objects
+// it writes live under the transcribe/<jobName>/ prefix in bucket.
+func newAWSTranscriber(cfg aws.Config, bucket, jobName string) *awsTranscriber {
+	return &awsTranscriber{
+		s3Client:         s3.NewFromConfig(cfg),
+		transcribeClient: transcribe.NewFromConfig(cfg),
+		bucket:           bucket,
+		jobName:          jobName,
+		keyPrefix:        fmt.Sprintf("transcribe/%s/", jobName),
+	}
+}
+
+func (a *awsTranscriber) Transcribe(ctx context.Context, audioPath string) (Transcript, error) {
+	var output asrOutput
+
+	if duration, err := probeDuration(audioPath); err == nil && duration > defaultChunkThreshold {
+		// The chunked path uploads the source as overlapping chunks already;
+		// uploading the whole file again first would push every byte to S3
+		// twice for exactly the large inputs chunking exists to handle.
+		chunkedOutput, err := runChunkedTranscription(ctx, a.transcribeClient, a.s3Client, a.bucket, a.keyPrefix, audioPath, a.jobName)
+		if err != nil {
+			return Transcript{}, err
+		}
+		a.chunked = true
+		output = chunkedOutput
+	} else {
+		audioKey, err := a.uploadFile(ctx, audioPath, a.keyPrefix+"source"+filepath.Ext(audioPath))
+		if err != nil {
+			return Transcript{}, fmt.Errorf("error uploading source audio: %w", err)
+		}
+		a.audioKey = audioKey
+
+		singleOutput, err := a.transcribeWhole(ctx, audioKey)
+		if err != nil {
+			return Transcript{}, err
+		}
+		output = singleOutput
+	}
+
+	return asrOutputToTranscript(output), nil
+}
+
+// transcribeWhole runs the non-chunked path against the already-uploaded
+// audioKey: start one transcription job, poll until it completes, and
+// download the result.
+func (a *awsTranscriber) transcribeWhole(ctx context.Context, audioKey string) (asrOutput, error) {
+	jsonKey := a.keyPrefix + "transcript-raw.json"
+	jobInput := &transcribe.StartTranscriptionJobInput{
+		TranscriptionJobName: aws.String(a.jobName),
+		Media: &types.Media{
+			MediaFileUri: aws.String(fmt.Sprintf("s3://%s/%s", a.bucket, audioKey)),
+		},
+		LanguageCode:     types.LanguageCodeEnUs,
+		OutputBucketName: aws.String(a.bucket),
+		OutputKey:        aws.String(jsonKey),
+	}
+
+	fmt.Printf("starting transcription job %s...\n", a.jobName)
+	if _, err := a.transcribeClient.StartTranscriptionJob(ctx, jobInput); err != nil {
+		return asrOutput{}, fmt.Errorf("error starting transcription job: %w", err)
+	}
+
+	fmt.Println("waiting for transcription to complete...")
+	startTime := time.Now()
+	for {
+		statusOutput, err := a.transcribeClient.GetTranscriptionJob(ctx, &transcribe.GetTranscriptionJobInput{
+			TranscriptionJobName: aws.String(a.jobName),
+		})
+		if err != nil {
+			return asrOutput{}, fmt.Errorf("error checking job status: %w", err)
+		}
+
+		// calculate progress based on elapsed time (rough estimate)
+		elapsed := time.Since(startTime)
+		progress := int(elapsed.Seconds() / 2) // assume 2 seconds per percent
+		if progress > 100 {
+			progress = 100
+		}
+		printProgress("Transcribing...", progress)
+
+		if statusOutput.TranscriptionJob.TranscriptionJobStatus == types.TranscriptionJobStatusCompleted {
+			fmt.Println("\nTranscription completed!")
+			break
+		} else if statusOutput.TranscriptionJob.TranscriptionJobStatus == types.TranscriptionJobStatusFailed {
+			return asrOutput{}, fmt.Errorf("transcription job %s failed", a.jobName)
+		}
+
+		time.Sleep(1 * time.Second)
+	}
+
+	fmt.Println("processing transcript...")
+	return downloadTranscript(ctx, a.s3Client, a.bucket, jsonKey)
+}
+
+// uploadFile uploads the local file at path to key via the multipart
+// uploader and returns key for convenience.
+func (a *awsTranscriber) uploadFile(ctx context.Context, path, key string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("error opening %s: %w", path, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", fmt.Errorf("error reading %s: %w", path, err)
+	}
+
+	fmt.Printf("uploading %s to s3...\n", key)
+	if err := newUploader(a.s3Client).Upload(ctx, a.bucket, key, file, info.Size()); err != nil {
+		return "", fmt.Errorf("error uploading to s3: %w", err)
+	}
+
+	return key, nil
+}
+
+// PresignOutputs uploads the local JSON/TXT transcript outputs alongside the
+// source audio under keyPrefix, then returns presigned GET URLs for all
+// three, valid for ttl. audioURL is empty when the source was transcribed
+// via the chunked path, which never uploads the whole file as a single
+// "source" object.
+func (a *awsTranscriber) PresignOutputs(ctx context.Context, jsonPath, txtPath string, ttl time.Duration) (audioURL, jsonURL, txtURL string, err error) {
+	jsonKey, err := a.uploadFile(ctx, jsonPath, a.keyPrefix+"transcript.json")
+	if err != nil {
+		return "", "", "", err
+	}
+
+	txtKey, err := a.uploadFile(ctx, txtPath, a.keyPrefix+"transcript.txt")
+	if err != nil {
+		return "", "", "", err
+	}
+
+	presignClient := s3.NewPresignClient(a.s3Client)
+
+	if a.audioKey != "" {
+		audioURL, err = presignGetObject(ctx, presignClient, a.bucket, a.audioKey, ttl)
+		if err != nil {
+			return "", "", "", err
+		}
+	}
+	jsonURL, err = presignGetObject(ctx, presignClient, a.bucket, jsonKey, ttl)
+	if err != nil {
+		return "", "", "", err
+	}
+	txtURL, err = presignGetObject(ctx, presignClient, a.bucket, txtKey, ttl)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	return audioURL, jsonURL, txtURL, nil
+}
+
+// presignGetObject returns a presigned GET URL for bucket/key, valid for ttl.
+func presignGetObject(ctx context.Context, client *s3.PresignClient, bucket, key string, ttl time.Duration) (string, error) {
+	req, err := client.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", fmt.Errorf("error presigning %s: %w", key, err)
+	}
+	return req.URL, nil
+}
+
+// Cleanup deletes the uploaded source audio, leaving the transcript outputs
+// in place. On the chunked path there's no single "source" object to delete
+// (chunking uploads the input as overlapping chunks instead), so every chunk
+// audio/transcript object under keyPrefix+"chunks/" is deleted instead.
+func (a *awsTranscriber) Cleanup(ctx context.Context) error {
+	if a.chunked {
+		return a.cleanupChunks(ctx)
+	}
+
+	if a.audioKey == "" {
+		return nil
+	}
+
+	_, err := a.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(a.bucket),
+		Key:    aws.String(a.audioKey),
+	})
+	if err != nil {
+		return fmt.Errorf("error deleting uploaded source audio: %w", err)
+	}
+
+	return nil
+}
+
+// cleanupChunks deletes every object the chunked path uploaded under
+// keyPrefix+"chunks/": each chunk's source audio and its raw Transcribe JSON.
+func (a *awsTranscriber) cleanupChunks(ctx context.Context) error {
+	prefix := a.keyPrefix + "chunks/"
+
+	list, err := a.s3Client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+		Bucket: aws.String(a.bucket),
+		Prefix: aws.String(prefix),
+	})
+	if err != nil {
+		return fmt.Errorf("error listing chunk objects: %w", err)
+	}
+
+	for _, obj := range list.Contents {
+		if _, err := a.s3Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+			Bucket: aws.String(a.bucket),
+			Key:    obj.Key,
+		}); err != nil {
+			return fmt.Errorf("error deleting %s: %w", aws.ToString(obj.Key), err)
+		}
+	}
+
+	return nil
+}
+
+// asrOutputToTranscript converts a raw Transcribe results payload into the
+// backend-agnostic Transcript type, one segment per word with trailing
+// punctuation folded into the preceding word's text.
+func asrOutputToTranscript(output asrOutput) Transcript {
+	var t Transcript
+
+	for _, item := range output.Results.Items {
+		if len(item.Alternatives) == 0 {
+			continue
+		}
+		content := item.Alternatives[0].Content
+
+		if item.Type == "punctuation" && len(t.Segments) > 0 {
+			t.Segments[len(t.Segments)-1].Text += content
+			continue
+		}
+
+		segment := TranscriptSegment{Text: content}
+		if item.StartTime != "" {
+			if start, err := strconv.ParseFloat(item.StartTime, 64); err == nil {
+				segment.Start = time.Duration(start * float64(time.Second))
+			}
+		}
+		if item.EndTime != "" {
+			if end, err := strconv.ParseFloat(item.EndTime, 64); err == nil {
+				segment.End = time.Duration(end * float64(time.Second))
+			}
+		}
+		if confidence, err := strconv.ParseFloat(item.Alternatives[0].Confidence, 64); err == nil {
+			segment.Confidence = confidence
+		}
+
+		t.Segments = append(t.Segments, segment)
+	}
+
+	return t
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// whisperLocalTranscriber shells out to a whisper.cpp binary so users
+// without AWS credentials (or without network access at all) can still
+// transcribe audio.
+type whisperLocalTranscriber struct {
+	binaryPath string
+	modelPath  string
+}
+
+// newWhisperLocalTranscriber builds a whisperLocalTranscriber from the
+// WHISPER_CPP_BIN (default "whisper") and WHISPER_CPP_MODEL env vars.
+func newWhisperLocalTranscriber() *whisperLocalTranscriber {
+	binaryPath := os.Getenv("WHISPER_CPP_BIN")
+	if binaryPath == "" {
+		binaryPath = "whisper"
+	}
+	return &whisperLocalTranscriber{
+		binaryPath: binaryPath,
+		modelPath:  os.Getenv("WHISPER_CPP_MODEL"),
+	}
+}
+
+func (w *whisperLocalTranscriber) Transcribe(ctx context.Context, audioPath string) (Transcript, error) {
+	if w.modelPath == "" {
+		return Transcript{}, fmt.Errorf("WHISPER_CPP_MODEL must be set to a whisper.cpp model file")
+	}
+
+	outPrefix := strings.TrimSuffix(audioPath, filepath.Ext(audioPath))
+
+	fmt.Println("transcribing with whisper.cpp...")
+	cmd := exec.CommandContext(ctx, w.binaryPath, "-m", w.modelPath, "-f", audioPath, "-oj", "-of", outPrefix)
+	if err := cmd.Run(); err != nil {
+		return Transcript{}, fmt.Errorf("error running whisper.cpp: %w", err)
+	}
+
+	data, err := os.ReadFile(outPrefix + ".json")
+	if err != nil {
+		return Transcript{}, fmt.Errorf("error reading whisper.cpp output: %w", err)
+	}
+
+	return parseWhisperCppOutput(data)
+}
+
+// whisperCppOutput is the subset of whisper.cpp's -oj JSON output we need.
+type whisperCppOutput struct {
+	Transcription []struct {
+		Offsets struct {
+			From int64 `json:"from"`
+			To   int64 `json:"to"`
+		} `json:"offsets"`
+		Text string `json:"text"`
+	} `json:"transcription"`
+}
+
+func parseWhisperCppOutput(data []byte) (Transcript, error) {
+	var out whisperCppOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return Transcript{}, fmt.Errorf("error parsing whisper.cpp output: %w", err)
+	}
+
+	var t Transcript
+	for _, seg := range out.Transcription {
+		t.Segments = append(t.Segments, TranscriptSegment{
+			Text:  strings.TrimSpace(seg.Text),
+			Start: time.Duration(seg.Offsets.From) * time.Millisecond,
+			End:   time.Duration(seg.Offsets.To) * time.Millisecond,
+		})
+	}
+	return t, nil
+}
+
+// whisperAPITranscriber calls the OpenAI Whisper HTTP API.
+type whisperAPITranscriber struct {
+	apiKey string
+	model  string
+	client *http.Client
+}
+
+// newWhisperAPITranscriber builds a whisperAPITranscriber from the
+// OPENAI_API_KEY and OPENAI_WHISPER_MODEL (default "whisper-1") env vars.
+func newWhisperAPITranscriber() *whisperAPITranscriber {
+	model := os.Getenv("OPENAI_WHISPER_MODEL")
+	if model == "" {
+		model = "whisper-1"
+	}
+	return &whisperAPITranscriber{
+		apiKey: os.Getenv("OPENAI_API_KEY"),
+		model:  model,
+		client: &http.Client{Timeout: 10 * time.Minute},
+	}
+}
+
+func (w *whisperAPITranscriber) Transcribe(ctx context.Context, audioPath string) (Transcript, error) {
+	if w.apiKey == "" {
+		return Transcript{}, fmt.Errorf("OPENAI_API_KEY must be set to use the whisper-api backend")
+	}
+
+	file, err := os.Open(audioPath)
+	if err != nil {
+		return Transcript{}, fmt.Errorf("error opening audio file: %w", err)
+	}
+	defer file.Close()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	part, err := writer.CreateFormFile("file", filepath.Base(audioPath))
+	if err != nil {
+		return Transcript{}, fmt.Errorf("error building whisper api request: %w", err)
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return Transcript{}, fmt.Errorf("error reading audio file: %w", err)
+	}
+	if err := writer.WriteField("model", w.model); err != nil {
+		return Transcript{}, fmt.Errorf("error building whisper api request: %w", err)
+	}
+	if err := writer.WriteField("response_format", "verbose_json"); err != nil {
+		return Transcript{}, fmt.Errorf("error building whisper api request: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return Transcript{}, fmt.Errorf("error building whisper api request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.openai.com/v1/audio/transcriptions", &body)
+	if err != nil {
+		return Transcript{}, fmt.Errorf("error building whisper api request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+w.apiKey)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	fmt.Println("transcribing with the openai whisper api...")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return Transcript{}, fmt.Errorf("error calling whisper api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Transcript{}, fmt.Errorf("error reading whisper api response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Transcript{}, fmt.Errorf("whisper api returned %s: %s", resp.Status, data)
+	}
+
+	return parseWhisperAPIResponse(data)
+}
+
+// whisperAPIResponse is the subset of the OpenAI verbose_json transcription
+// response we need.
+type whisperAPIResponse struct {
+	Segments []struct {
+		Start      float64 `json:"start"`
+		End        float64 `json:"end"`
+		Text       string  `json:"text"`
+		AvgLogprob float64 `json:"avg_logprob"`
+	} `json:"segments"`
+}
+
+func parseWhisperAPIResponse(data []byte) (Transcript, error) {
+	var out whisperAPIResponse
+	if err := json.Unmarshal(data, &out); err != nil {
+		return Transcript{}, fmt.Errorf("error parsing whisper api response: %w", err)
+	}
+
+	var t Transcript
+	for _, seg := range out.Segments {
+		t.Segments = append(t.Segments, TranscriptSegment{
+			Text:  strings.TrimSpace(seg.Text),
+			Start: time.Duration(seg.Start * float64(time.Second)),
+			End:   time.Duration(seg.End * float64(time.Second)),
+			// OpenAI doesn't return a direct confidence score; approximate
+			// one from the average per-token log probability.
+			Confidence: math.Exp(seg.AvgLogprob),
+		})
+	}
+	return t, nil
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const (
+	defaultPartSize      = 16 * 1024 * 1024
+	defaultUploadWorkers = 4
+)
+
+// uploader drives a multipart upload to S3 through a bounded pool of worker
+// goroutines, so large audio/video files never have to fit fully in memory
+// and never hit the 5GB PutObject limit.
+type uploader struct {
+	client   *s3.Client
+	partSize int64
+	workers  int
+}
+
+// newUploader returns an uploader configured with the repo's default part
+// size and worker count.
+func newUploader(client *s3.Client) *uploader {
+	return &uploader{
+		client:   client,
+		partSize: defaultPartSize,
+		workers:  defaultUploadWorkers,
+	}
+}
+
+// uploadPart is a single part read from the source body, staged for a worker
+// to upload.
+type uploadPart struct {
+	number int32
+	data   []byte
+}
+
+// Upload streams body to bucket/key as a multipart upload, reading partSize
+// chunks and fanning them out across the worker pool. totalSize is used only
+// to report progress as a percentage through printProgress; pass 0 when the
+// size isn't known up front (e.g. a streamed YouTube download), in which case
+// progress is reported as a raw byte count instead. Any read/upload failure
+// or context cancellation aborts the multipart upload before returning.
+func (u *uploader) Upload(ctx context.Context, bucket, key string, body io.Reader, totalSize int64) error {
+	created, err := u.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:            aws.String(bucket),
+		Key:               aws.String(key),
+		ChecksumAlgorithm: types.ChecksumAlgorithmSha256,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating multipart upload: %w", err)
+	}
+	uploadID := created.UploadId
+
+	abort := func() {
+		_, abortErr := u.client.AbortMultipartUpload(context.Background(), &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(bucket),
+			Key:      aws.String(key),
+			UploadId: uploadID,
+		})
+		if abortErr != nil {
+			fmt.Printf("error aborting multipart upload: %v\n", abortErr)
+		}
+	}
+
+	workerCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	parts := make(chan uploadPart)
+	errCh := make(chan error, u.workers)
+
+	var (
+		mu        sync.Mutex
+		completed []types.CompletedPart
+		acked     int64
+		wg        sync.WaitGroup
+	)
+
+	for i := 0; i < u.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for part := range parts {
+				sum := sha256.Sum256(part.data)
+				checksum := base64.StdEncoding.EncodeToString(sum[:])
+
+				out, err := u.client.UploadPart(workerCtx, &s3.UploadPartInput{
+					Bucket:         aws.String(bucket),
+					Key:            aws.String(key),
+					UploadId:       uploadID,
+					PartNumber:     aws.Int32(part.number),
+					Body:           bytes.NewReader(part.data),
+					ChecksumSHA256: aws.String(checksum),
+				})
+				if err != nil {
+					select {
+					case errCh <- fmt.Errorf("error uploading part %d: %w", part.number, err):
+					default:
+					}
+					cancel()
+					return
+				}
+
+				mu.Lock()
+				completed = append(completed, types.CompletedPart{
+					ETag:           out.ETag,
+					PartNumber:     aws.Int32(part.number),
+					ChecksumSHA256: aws.String(checksum),
+				})
+				mu.Unlock()
+
+				total := atomic.AddInt64(&acked, int64(len(part.data)))
+				printUploadProgress(total, totalSize)
+			}
+		}()
+	}
+
+	readErr := readParts(workerCtx, body, u.partSize, parts)
+
+	wg.Wait()
+	close(errCh)
+
+	if uploadErr, ok := <-errCh; ok {
+		abort()
+		return uploadErr
+	}
+	if readErr != nil {
+		abort()
+		return readErr
+	}
+	if ctx.Err() != nil {
+		abort()
+		return ctx.Err()
+	}
+
+	sort.Slice(completed, func(i, j int) bool {
+		return *completed[i].PartNumber < *completed[j].PartNumber
+	})
+
+	_, err = u.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
+		UploadId: uploadID,
+		MultipartUpload: &types.CompletedMultipartUpload{
+			Parts: completed,
+		},
+	})
+	if err != nil {
+		abort()
+		return fmt.Errorf("error completing multipart upload: %w", err)
+	}
+
+	fmt.Println()
+	return nil
+}
+
+// readParts reads partSize chunks from body and sends them on parts until
+// body is exhausted, the context is cancelled, or a read fails. It always
+// closes parts before returning.
+func readParts(ctx context.Context, body io.Reader, partSize int64, parts chan<- uploadPart) error {
+	defer close(parts)
+
+	buf := make([]byte, partSize)
+	var partNumber int32 = 1
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		n, err := io.ReadFull(body, buf)
+		if n > 0 {
+			data := make([]byte, n)
+			copy(data, buf[:n])
+
+			select {
+			case parts <- uploadPart{number: partNumber, data: data}:
+				partNumber++
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading input: %w", err)
+		}
+	}
+}
+
+// printUploadProgress reports bytes acknowledged across all workers through
+// the shared progress bar, as a percentage when totalSize is known or as a
+// raw byte count otherwise.
+func printUploadProgress(acked, totalSize int64) {
+	if totalSize > 0 {
+		progress := int(acked * 100 / totalSize)
+		if progress > 100 {
+			progress = 100
+		}
+		printProgress("uploading to s3...", progress)
+		return
+	}
+	fmt.Printf("\r\033[Kuploading to s3... %s uploaded", formatBytes(acked))
+}
+
+// formatBytes renders a byte count using binary (KiB/MiB/...) units.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for n2 := n / unit; n2 >= unit; n2 /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// writeTranscriptJSON serializes t as indented JSON to path.
+func writeTranscriptJSON(t Transcript, path string) error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding transcript json: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing transcript json file: %w", err)
+	}
+	return nil
+}
+
+// writeTranscriptText writes t's flattened text to path.
+func writeTranscriptText(t Transcript, path string) error {
+	if err := os.WriteFile(path, []byte(t.Text()), 0644); err != nil {
+		return fmt.Errorf("error writing transcript text file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/kkdai/youtube/v2"
+)
+
+// youtubeURLPattern matches youtube.com/watch, youtu.be, and bare 11-char video IDs
+var youtubeURLPattern = regexp.MustCompile(`(?:youtube\.com/watch\?v=|youtu\.be/)([A-Za-z0-9_-]{11})|^([A-Za-z0-9_-]{11})$`)
+
+// isYouTubeInput reports whether input looks like a YouTube URL or bare video ID
+// rather than a local file path.
+func isYouTubeInput(input string) bool {
+	return youtubeURLPattern.MatchString(input)
+}
+
+// progressReader wraps an io.Reader and reports percent-complete against total
+// through printProgress as bytes are read.
+type progressReader struct {
+	io.Reader
+	message string
+	total   int64
+	read    int64
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	n, err := pr.Reader.Read(p)
+	pr.read += int64(n)
+	if pr.total > 0 {
+		progress := int(pr.read * 100 / pr.total)
+		if progress > 100 {
+			progress = 100
+		}
+		printProgress(pr.message, progress)
+	}
+	return n, err
+}
+
+// youtubeAudio holds the result of resolving and downloading a YouTube video's
+// audio-only stream, transcoded to mp3.
+type youtubeAudio struct {
+	Reader  io.Reader
+	Title   string
+	VideoID string
+}
+
+// downloadYouTubeAudio resolves the highest-bitrate audio-only format for the
+// given YouTube URL/ID (or the format matching formatItag if non-zero),
+// streams it through ffmpeg to transcode to mp3, and returns an io.Reader of
+// the resulting mp3 bytes. No intermediate files are written to disk.
+func downloadYouTubeAudio(ctx context.Context, input string, formatItag int) (*youtubeAudio, error) {
+	client := youtube.Client{}
+
+	video, err := client.GetVideoContext(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving youtube video: %w", err)
+	}
+
+	var format *youtube.Format
+	if formatItag != 0 {
+		f := video.Formats.FindByItag(formatItag)
+		if f == nil {
+			return nil, fmt.Errorf("no format with itag %d found for video %s", formatItag, video.ID)
+		}
+		format = f
+	} else {
+		audioFormats := video.Formats.Type("audio")
+		if len(audioFormats) == 0 {
+			return nil, fmt.Errorf("no audio-only formats found for video %s", video.ID)
+		}
+		audioFormats.Sort()
+		format = &audioFormats[0]
+	}
+
+	stream, size, err := client.GetStreamContext(ctx, video, format)
+	if err != nil {
+		return nil, fmt.Errorf("error opening youtube stream: %w", err)
+	}
+
+	progress := &progressReader{
+		Reader:  stream,
+		message: fmt.Sprintf("downloading %s...", video.Title),
+		total:   size,
+	}
+
+	container := youtubeContainer(format.MimeType)
+
+	cmd := exec.Command("ffmpeg", "-f", container, "-i", "pipe:0", "-acodec", "libmp3lame", "-f", "mp3", "pipe:1")
+	cmd.Stdin = progress
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("error attaching ffmpeg stdout: %w", err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("error starting ffmpeg: %w", err)
+	}
+
+	return &youtubeAudio{
+		Reader:  &waitCloserReader{Reader: stdout, cmd: cmd, stream: stream},
+		Title:   video.Title,
+		VideoID: video.ID,
+	}, nil
+}
+
+// youtubeContainer extracts the container name (e.g. "webm", "mp4") ffmpeg
+// expects for -f from a YouTube format's MIME type, e.g. "audio/webm; codecs=\"opus\"".
+func youtubeContainer(mimeType string) string {
+	mainType := strings.SplitN(mimeType, ";", 2)[0]
+	parts := strings.SplitN(mainType, "/", 2)
+	if len(parts) != 2 {
+		return "mp4"
+	}
+	return parts[1]
+}
+
+// waitCloserReader reads from an ffmpeg stdout pipe and waits for the process
+// to exit once the reader is fully drained, surfacing any ffmpeg failure. It
+// also owns the youtube stream ffmpeg's stdin was copying from, closing it
+// only once ffmpeg (and therefore the stdin copy goroutine started by
+// os/exec) is done with it.
+type waitCloserReader struct {
+	io.Reader
+	cmd    *exec.Cmd
+	stream io.ReadCloser
+	waited bool
+}
+
+func (w *waitCloserReader) Read(p []byte) (int, error) {
+	n, err := w.Reader.Read(p)
+	if err == io.EOF && !w.waited {
+		w.waited = true
+		waitErr := w.cmd.Wait()
+		w.stream.Close()
+		if waitErr != nil {
+			return n, fmt.Errorf("ffmpeg failed: %w", waitErr)
+		}
+	}
+	return n, err
+}
+
+// parseItag parses the --youtube-format flag value into an itag, returning 0
+// (meaning "auto-select highest bitrate") when empty.
+func parseItag(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	itag, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --youtube-format %q: %w", value, err)
+	}
+	return itag, nil
+}